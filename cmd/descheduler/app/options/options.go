@@ -0,0 +1,117 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options defines DeschedulerServer, the configuration struct every
+// strategy and the descheduler command read from, and the flags that
+// populate it.
+package options
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	clientset "k8s.io/client-go/kubernetes"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/plan"
+)
+
+// defaultEvictionTimeout is used whenever EvictionTimeout is left at its
+// zero value, so a PDB-aware eviction always gets a real retry window
+// instead of a deadline that has already elapsed by the time the first
+// backoff attempt checks it.
+const defaultEvictionTimeout = 30 * time.Second
+
+// LeaderElectionConfiguration groups the --leader-elect* flags.
+type LeaderElectionConfiguration struct {
+	// LeaderElect enables running the descheduler as a long-lived
+	// controller that only acts while holding the configured lease, instead
+	// of exiting after a single pass.
+	LeaderElect       bool
+	LeaseDuration     time.Duration
+	ResourceName      string
+	ResourceNamespace string
+}
+
+// DeschedulerServer holds the configuration needed to run the descheduler.
+// AddFlags populates the CLI-driven fields; Client, MetricsClient and
+// PlanRecorder are constructed by the command after flag parsing, since
+// they depend on each other and on in-cluster/kubeconfig discovery.
+type DeschedulerServer struct {
+	Client        clientset.Interface
+	MetricsClient metricsclientset.Interface
+	PlanRecorder  *plan.Recorder
+
+	KubeconfigFile   string
+	PolicyConfigFile string
+
+	DryRun                    bool
+	PDBAware                  bool
+	EvictionTimeout           time.Duration
+	EvictionGracePeriod       time.Duration
+	MaxNoOfPodsToEvictPerNode int
+
+	DeschedulingInterval time.Duration
+	LeaderElection       LeaderElectionConfiguration
+
+	DisableMetrics     bool
+	MetricsBindAddress string
+
+	// PlanOutput selects the format PlanRecorder renders in ("json", "yaml"
+	// or "table"); empty disables plan recording unless DryRun is set, in
+	// which case the plan is rendered as a table.
+	PlanOutput string
+	// PlanFile is where the recorder's output is written; empty writes to
+	// stdout.
+	PlanFile string
+}
+
+// NewDeschedulerServer returns a DeschedulerServer with the defaults used
+// when a flag is left unset.
+func NewDeschedulerServer() *DeschedulerServer {
+	return &DeschedulerServer{
+		EvictionTimeout:    defaultEvictionTimeout,
+		MetricsBindAddress: ":10258",
+		LeaderElection: LeaderElectionConfiguration{
+			LeaseDuration:     15 * time.Second,
+			ResourceName:      "descheduler",
+			ResourceNamespace: "kube-system",
+		},
+	}
+}
+
+// AddFlags registers every descheduler command-line flag onto fs.
+func (rs *DeschedulerServer) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&rs.KubeconfigFile, "kubeconfig", rs.KubeconfigFile, "File with kubeconfig for accessing a remote cluster; in-cluster config is used if unset.")
+	fs.StringVar(&rs.PolicyConfigFile, "policy-config-file", rs.PolicyConfigFile, "File with the descheduler policy configuration.")
+	fs.BoolVar(&rs.DryRun, "dry-run", rs.DryRun, "Run the descheduler without evicting any pods.")
+	fs.BoolVar(&rs.PDBAware, "pdb-aware", rs.PDBAware, "Retry an eviction rejected by a PodDisruptionBudget instead of giving up on the pod immediately.")
+	fs.DurationVar(&rs.EvictionTimeout, "eviction-timeout", rs.EvictionTimeout, "How long to keep retrying a PDB-aware eviction before giving up.")
+	fs.DurationVar(&rs.EvictionGracePeriod, "eviction-grace-period", rs.EvictionGracePeriod, "Grace period used when evicting pods; 0 defers to each pod's own terminationGracePeriodSeconds.")
+	fs.IntVar(&rs.MaxNoOfPodsToEvictPerNode, "max-pods-to-evict-per-node", rs.MaxNoOfPodsToEvictPerNode, "Maximum number of pods a single descheduler run may evict from any one node; 0 means unlimited.")
+
+	fs.DurationVar(&rs.DeschedulingInterval, "descheduling-interval", rs.DeschedulingInterval, "Time between descheduler runs; 0 runs once and exits.")
+	fs.BoolVar(&rs.LeaderElection.LeaderElect, "leader-elect", rs.LeaderElection.LeaderElect, "Run as a long-lived controller, only acting while holding a leader-election lease.")
+	fs.DurationVar(&rs.LeaderElection.LeaseDuration, "leader-elect-lease-duration", rs.LeaderElection.LeaseDuration, "Duration non-leader candidates wait before attempting to acquire the leader-election lease.")
+	fs.StringVar(&rs.LeaderElection.ResourceNamespace, "leader-elect-resource-namespace", rs.LeaderElection.ResourceNamespace, "Namespace of the lease object used for leader election.")
+
+	fs.BoolVar(&rs.DisableMetrics, "disable-metrics", rs.DisableMetrics, "Disable serving /metrics, /healthz and /readyz.")
+	fs.StringVar(&rs.MetricsBindAddress, "metrics-bind-address", rs.MetricsBindAddress, "Address the metrics/health server listens on.")
+
+	fs.StringVar(&rs.PlanOutput, "output-plan", rs.PlanOutput, `Record candidate evictions as a plan instead of acting on trust alone; one of "json", "yaml" or "table". Unset disables plan recording unless --dry-run is set, which records it as a table.`)
+	fs.StringVar(&rs.PlanFile, "plan-file", rs.PlanFile, "File the recorded plan is written to; unset writes to stdout.")
+}
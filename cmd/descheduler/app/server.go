@@ -0,0 +1,137 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package app wires a DeschedulerServer and the registered
+// strategies.Registry into a runnable descheduler: a single pass by
+// default, a ticker-driven loop when --descheduling-interval is set, and a
+// leader-elected long-lived controller when --leader-elect is also set.
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/golang/glog"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/kubernetes-incubator/descheduler/cmd/descheduler/app/options"
+	"github.com/kubernetes-incubator/descheduler/pkg/api"
+	nodeutil "github.com/kubernetes-incubator/descheduler/pkg/descheduler/node"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/strategies"
+)
+
+// policyGroupVersion is the eviction subresource's API group/version; every
+// cluster this descheduler targets supports policy/v1beta1 evictions.
+const policyGroupVersion = "policy/v1beta1"
+
+// Run starts the descheduler. With LeaderElection disabled it runs
+// directly; otherwise it only runs while holding the configured lease,
+// stopping cleanly when it loses leadership or ctx is cancelled.
+func Run(ctx context.Context, rs *options.DeschedulerServer) error {
+	if !rs.LeaderElection.LeaderElect {
+		return RunDeschedulerStrategies(ctx, rs)
+	}
+
+	id, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      rs.LeaderElection.ResourceName,
+			Namespace: rs.LeaderElection.ResourceNamespace,
+		},
+		Client:     rs.Client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: id},
+	}
+
+	var runErr error
+	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: rs.LeaderElection.LeaseDuration,
+		RenewDeadline: rs.LeaderElection.LeaseDuration * 2 / 3,
+		RetryPeriod:   rs.LeaderElection.LeaseDuration / 3,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				runErr = RunDeschedulerStrategies(ctx, rs)
+			},
+			OnStoppedLeading: func() {
+				glog.V(1).Infof("%s: lost leadership, stopping the descheduling loop", id)
+			},
+		},
+	})
+	return runErr
+}
+
+// RunDeschedulerStrategies loads the configured policy and runs every
+// enabled strategy against the cluster's ready nodes once, then again every
+// rs.DeschedulingInterval until ctx is cancelled. A zero DeschedulingInterval
+// runs a single pass and returns.
+func RunDeschedulerStrategies(ctx context.Context, rs *options.DeschedulerServer) error {
+	policy, err := api.LoadPolicyConfig(rs.PolicyConfigFile)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := runOnce(ctx, rs, policy); err != nil {
+			return err
+		}
+		if rs.DeschedulingInterval <= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(rs.DeschedulingInterval):
+		}
+	}
+}
+
+// runOnce runs every enabled strategy in policy once against the cluster's
+// current ready nodes, sharing a single nodePodEvictedCount across them so
+// ds.MaxNoOfPodsToEvictPerNode is enforced cumulatively for the pass.
+func runOnce(ctx context.Context, rs *options.DeschedulerServer, policy *api.DeschedulerPolicy) error {
+	nodes, err := nodeutil.ReadyNodes(rs.Client)
+	if err != nil {
+		return err
+	}
+
+	// nodePodEvictedCount's underlying type is map[*v1.Node]int, so this
+	// unnamed map literal is assignable to it without strategies exporting
+	// a constructor for the type.
+	nodepodCount := map[*v1.Node]int{}
+	for name, strategy := range policy.Strategies {
+		if !strategy.Enabled {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		result, err := strategies.DefaultRegistry.Run(ctx, name, rs, strategy, policyGroupVersion, nodes, nodepodCount)
+		if err != nil {
+			glog.Errorf("strategy %q failed: %v", name, err)
+			continue
+		}
+		glog.V(1).Infof("strategy %q evicted %d pod(s), skipped %#v", name, result.PodsEvicted, result.PodsSkipped)
+	}
+	return nil
+}
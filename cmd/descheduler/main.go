@@ -0,0 +1,86 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/golang/glog"
+	"github.com/spf13/pflag"
+
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/kubernetes-incubator/descheduler/cmd/descheduler/app"
+	"github.com/kubernetes-incubator/descheduler/cmd/descheduler/app/options"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/metrics"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/plan"
+)
+
+func main() {
+	rs := options.NewDeschedulerServer()
+	rs.AddFlags(pflag.CommandLine)
+	pflag.Parse()
+
+	config, err := clientcmd.BuildConfigFromFlags("", rs.KubeconfigFile)
+	if err != nil {
+		glog.Fatalf("unable to build client config: %v", err)
+	}
+	rs.Client, err = clientset.NewForConfig(config)
+	if err != nil {
+		glog.Fatalf("unable to build client: %v", err)
+	}
+	if rs.MetricsClient, err = metricsclientset.NewForConfig(config); err != nil {
+		glog.Errorf("unable to build metrics client, usage will fall back to summed pod requests: %v", err)
+		rs.MetricsClient = nil
+	}
+
+	planOut := io.Writer(os.Stdout)
+	if rs.PlanOutput != "" || rs.DryRun {
+		rs.PlanRecorder = plan.NewRecorder(rs.PlanOutput)
+		if rs.PlanFile != "" {
+			f, err := os.Create(rs.PlanFile)
+			if err != nil {
+				glog.Fatalf("unable to open --plan-file %q: %v", rs.PlanFile, err)
+			}
+			defer f.Close()
+			planOut = f
+		}
+	}
+
+	if !rs.DisableMetrics {
+		go metrics.ListenAndServe(rs.MetricsBindAddress)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	runErr := app.Run(ctx, rs)
+	if rs.PlanRecorder != nil {
+		if err := rs.PlanRecorder.WriteTo(planOut); err != nil {
+			glog.Errorf("unable to write plan: %v", err)
+		}
+	}
+	if runErr != nil {
+		glog.Fatalf("descheduler run failed: %v", runErr)
+	}
+}
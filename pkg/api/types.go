@@ -0,0 +1,62 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package api defines the descheduler's policy configuration format: which
+// strategies are enabled and the parameters each one reads.
+package api
+
+import (
+	"k8s.io/api/core/v1"
+)
+
+// Percentage is a resource usage percentage, compared against a node's
+// allocatable for that resource.
+type Percentage float64
+
+// ResourceThresholds maps a resource name to the percentage threshold a
+// node's usage of that resource is compared against.
+type ResourceThresholds map[v1.ResourceName]Percentage
+
+// NodeResourceUtilizationThresholds configures the LowNodeUtilization
+// strategy: a node below every Thresholds entry is underutilized, a node
+// above any TargetThresholds entry is overutilized.
+type NodeResourceUtilizationThresholds struct {
+	Thresholds       ResourceThresholds `json:"thresholds,omitempty" yaml:"thresholds,omitempty"`
+	TargetThresholds ResourceThresholds `json:"targetThresholds,omitempty" yaml:"targetThresholds,omitempty"`
+}
+
+// StrategyParameters holds every parameter a strategy might read; a given
+// strategy only looks at the fields relevant to it.
+type StrategyParameters struct {
+	NodeResourceUtilizationThresholds NodeResourceUtilizationThresholds `json:"nodeResourceUtilizationThresholds,omitempty" yaml:"nodeResourceUtilizationThresholds,omitempty"`
+	// TopologyKeys, if set, scopes RemoveDuplicatePods' duplicate detection
+	// to pods colliding within the same topology domain (the joined value of
+	// these node label keys) rather than the same node.
+	TopologyKeys []string `json:"topologyKeys,omitempty" yaml:"topologyKeys,omitempty"`
+}
+
+// DeschedulerStrategy is a single strategy's configuration within a policy.
+type DeschedulerStrategy struct {
+	Enabled bool               `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Params  StrategyParameters `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// DeschedulerPolicy is the policy configuration file format, keyed by
+// strategy name (matching the name the strategy Registers itself under in
+// strategies.DefaultRegistry).
+type DeschedulerPolicy struct {
+	Strategies map[string]DeschedulerStrategy `json:"strategies,omitempty" yaml:"strategies,omitempty"`
+}
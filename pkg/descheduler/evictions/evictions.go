@@ -0,0 +1,62 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package evictions wraps the eviction subresource used to remove pods
+// while honoring any PodDisruptionBudget that matches them.
+package evictions
+
+import (
+	policy "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/api/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// EvictPod evicts pod via the policy/v1beta1 Eviction subresource, which the
+// API server resolves against any matching PodDisruptionBudget before
+// honoring it. gracePeriodSeconds, if non-nil, is passed through as the
+// eviction's DeleteOptions.GracePeriodSeconds; nil lets the pod's own
+// terminationGracePeriodSeconds apply as usual. dryRun short-circuits to a
+// no-op success so callers can preview what would be evicted.
+//
+// Every caller in this tree (pkg/descheduler/strategies/duplicates.go,
+// pkg/descheduler/strategies/lownodeutilization.go) passes gracePeriodSeconds
+// explicitly; a new strategy added later must do the same rather than
+// reintroducing a backward-compatible overload.
+func EvictPod(client clientset.Interface, pod *v1.Pod, policyGroupVersion string, dryRun bool, gracePeriodSeconds *int64) (bool, error) {
+	if dryRun {
+		return true, nil
+	}
+	eviction := &policy.Eviction{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyGroupVersion,
+			Kind:       "Eviction",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+		},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: gracePeriodSeconds,
+		},
+	}
+	err := client.PolicyV1beta1().Evictions(eviction.Namespace).Evict(eviction)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
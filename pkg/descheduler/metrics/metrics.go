@@ -0,0 +1,80 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics exposes the descheduler's Prometheus metrics and the
+// /metrics, /healthz and /readyz HTTP endpoints that serve them.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"k8s.io/apiserver/pkg/server/mux"
+)
+
+var (
+	// PodsEvictedTotal counts every successful eviction, labeled by the
+	// strategy that performed it and the node/namespace it came from.
+	PodsEvictedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "descheduler_pods_evicted_total",
+		Help: "Number of pods evicted by the descheduler, broken down by strategy, node and namespace.",
+	}, []string{"strategy", "node", "namespace"})
+
+	// PodsSkippedTotal counts pods a strategy considered but did not evict,
+	// labeled by the reason (e.g. "pdb", "saturated", "max-pods-per-node").
+	PodsSkippedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "descheduler_pods_skipped_total",
+		Help: "Number of pods a strategy skipped evicting, broken down by reason.",
+	}, []string{"reason"})
+
+	// StrategyDurationSeconds observes how long each strategy run takes.
+	StrategyDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "descheduler_strategy_duration_seconds",
+		Help:    "Time taken to run a single descheduler strategy.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"strategy"})
+)
+
+func init() {
+	prometheus.MustRegister(PodsEvictedTotal, PodsSkippedTotal, StrategyDurationSeconds)
+}
+
+// NewHandler returns a PathRecorderMux serving /metrics, /healthz and
+// /readyz, ready to be passed to http.Serve by the caller.
+func NewHandler() *mux.PathRecorderMux {
+	m := mux.NewPathRecorderMux("descheduler")
+	m.Handle("/metrics", promhttp.Handler())
+	m.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	m.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return m
+}
+
+// ListenAndServe starts the metrics/health server on addr. It is intended to
+// be run in its own goroutine from the descheduler command; a failure is
+// logged rather than fatal since metrics are not required for descheduling
+// to proceed.
+func ListenAndServe(addr string) {
+	if err := http.ListenAndServe(addr, NewHandler()); err != nil {
+		glog.Errorf("metrics server exited: %v", err)
+	}
+}
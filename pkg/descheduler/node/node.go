@@ -0,0 +1,86 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package node holds node-related helpers shared by the descheduler's
+// strategies.
+package node
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// ReadyNodes returns the cluster's schedulable, Ready nodes.
+func ReadyNodes(client clientset.Interface) ([]*v1.Node, error) {
+	nodeList, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]*v1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		if node.Spec.Unschedulable || !IsReady(node) {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// IsReady reports whether node's NodeReady condition is true.
+func IsReady(node *v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// PodFitsCurrentNode reports whether pod's node selector is satisfied by
+// node.
+func PodFitsCurrentNode(pod *v1.Pod, node *v1.Node) bool {
+	for k, v := range pod.Spec.NodeSelector {
+		if node.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// PodToleratesNodeTaints reports whether pod tolerates every NoSchedule and
+// NoExecute taint on node.
+func PodToleratesNodeTaints(pod *v1.Pod, node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect != v1.TaintEffectNoSchedule && taint.Effect != v1.TaintEffectNoExecute {
+			continue
+		}
+		if !tolerated(pod.Spec.Tolerations, taint) {
+			return false
+		}
+	}
+	return true
+}
+
+func tolerated(tolerations []v1.Toleration, taint v1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(&taint) {
+			return true
+		}
+	}
+	return false
+}
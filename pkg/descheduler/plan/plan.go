@@ -0,0 +1,104 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plan records the candidate evictions a descheduler run
+// considered, for structured output via --output-plan. It lives in its own
+// package, rather than alongside the strategies that populate it, so that
+// cmd/descheduler/app/options can hold a Recorder on DeschedulerServer
+// without an import cycle through package strategies.
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Eviction describes a single candidate eviction a strategy decided on,
+// whether or not it was actually carried out. It is the unit recorded into
+// a Plan when the descheduler runs with --dry-run or --output-plan.
+type Eviction struct {
+	Pod              string   `json:"pod" yaml:"pod"`
+	Namespace        string   `json:"namespace" yaml:"namespace"`
+	Node             string   `json:"node" yaml:"node"`
+	Strategy         string   `json:"strategy" yaml:"strategy"`
+	Reason           string   `json:"reason" yaml:"reason"`
+	OwnerReferences  []string `json:"ownerReferences" yaml:"ownerReferences"`
+	CreatorSaturated bool     `json:"creatorSaturated" yaml:"creatorSaturated"`
+}
+
+// Plan is the machine-readable record of every candidate eviction observed
+// during a descheduler run.
+type Plan struct {
+	Evictions []Eviction `json:"evictions" yaml:"evictions"`
+}
+
+// Recorder collects Evictions as strategies run and renders them in one of
+// the supported formats once the run is complete.
+type Recorder struct {
+	format string
+
+	mu   sync.Mutex
+	plan Plan
+}
+
+// NewRecorder returns a Recorder rendering in format, one of "json", "yaml"
+// or "table". An unrecognized format falls back to "table".
+func NewRecorder(format string) *Recorder {
+	return &Recorder{format: format}
+}
+
+// Record appends ev to the plan. Safe for concurrent use.
+func (r *Recorder) Record(ev Eviction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plan.Evictions = append(r.plan.Evictions, ev)
+}
+
+// WriteTo renders the recorded plan to w in the recorder's configured format.
+func (r *Recorder) WriteTo(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r.plan)
+	case "yaml":
+		out, err := yaml.Marshal(r.plan)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(out)
+		return err
+	default:
+		return r.writeTable(w)
+	}
+}
+
+func (r *Recorder) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tNODE\tPOD\tSTRATEGY\tREASON")
+	for _, ev := range r.plan.Evictions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", ev.Namespace, ev.Node, ev.Pod, ev.Strategy, ev.Reason)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pod holds pod-related helpers shared by the descheduler's
+// strategies.
+package pod
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// ListPodsOnNode lists every pod scheduled onto node.
+func ListPodsOnNode(client clientset.Interface, node *v1.Node) ([]*v1.Pod, error) {
+	fieldSelector := fields.OneTermEqualSelector("spec.nodeName", node.Name).String()
+	podList, err := client.CoreV1().Pods(v1.NamespaceAll).List(metav1.ListOptions{FieldSelector: fieldSelector})
+	if err != nil {
+		return nil, err
+	}
+	pods := make([]*v1.Pod, 0, len(podList.Items))
+	for i := range podList.Items {
+		pods = append(pods, &podList.Items[i])
+	}
+	return pods, nil
+}
+
+// ListEvictablePodsOnNode lists node's pods that the descheduler is allowed
+// to evict.
+func ListEvictablePodsOnNode(client clientset.Interface, node *v1.Node) ([]*v1.Pod, error) {
+	pods, err := ListPodsOnNode(client, node)
+	if err != nil {
+		return nil, err
+	}
+	evictable := make([]*v1.Pod, 0, len(pods))
+	for _, p := range pods {
+		if IsEvictable(p) {
+			evictable = append(evictable, p)
+		}
+	}
+	return evictable, nil
+}
+
+// systemCriticalPriority is the lowest priority value Kubernetes reserves for
+// system-critical pods.
+const systemCriticalPriority = 2000000000
+
+// IsEvictable reports whether the descheduler may evict pod. Static
+// (mirror) pods and DaemonSet-owned pods are never evicted, since neither
+// is rescheduled by evicting it; pods with no owner reference at all aren't
+// evicted either, since there's nothing to recreate them. Critical pods
+// (priority at or above systemCriticalPriority) and pods using HostPath or
+// EmptyDir local storage are never evicted.
+func IsEvictable(pod *v1.Pod) bool {
+	if _, ok := pod.Annotations[v1.MirrorPodAnnotationKey]; ok {
+		return false
+	}
+	if pod.Spec.Priority != nil && *pod.Spec.Priority >= systemCriticalPriority {
+		return false
+	}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.HostPath != nil || vol.EmptyDir != nil {
+			return false
+		}
+	}
+	if len(pod.OwnerReferences) == 0 {
+		return false
+	}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+// OwnerRef returns pod's owner references.
+func OwnerRef(pod *v1.Pod) []metav1.OwnerReference {
+	return pod.OwnerReferences
+}
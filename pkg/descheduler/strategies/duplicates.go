@@ -17,9 +17,17 @@ limitations under the License.
 package strategies
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"strings"
+	"time"
 
 	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	"k8s.io/api/core/v1"
 	clientset "k8s.io/client-go/kubernetes"
@@ -27,91 +35,251 @@ import (
 	"github.com/kubernetes-incubator/descheduler/cmd/descheduler/app/options"
 	"github.com/kubernetes-incubator/descheduler/pkg/api"
 	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/evictions"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/metrics"
 	nodeutil "github.com/kubernetes-incubator/descheduler/pkg/descheduler/node"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/plan"
 	podutil "github.com/kubernetes-incubator/descheduler/pkg/descheduler/pod"
 )
 
+// strategyName is used to label metrics emitted by this strategy.
+const strategyName = "RemoveDuplicatePods"
+
 //type creator string
 type DuplicatePodsMap map[string][]*v1.Pod
 
+// minEvictionTimeout is the floor applied to ds.EvictionTimeout before it is
+// used as a retry deadline. options.NewDeschedulerServer already defaults
+// EvictionTimeout to a sane value, but a zero-value DeschedulerServer built
+// by hand (e.g. in a test) would otherwise produce a deadline of
+// time.Now().Add(0), which is already in the past by the time the backoff
+// condition runs, so the first attempt fails closed as errPDBViolated
+// without ever calling EvictPod.
+const minEvictionTimeout = 30 * time.Second
+
+// effectiveEvictionTimeout returns timeout, or minEvictionTimeout if timeout
+// is not positive.
+func effectiveEvictionTimeout(timeout time.Duration) time.Duration {
+	if timeout <= 0 {
+		return minEvictionTimeout
+	}
+	return timeout
+}
+
+// evictionRetryBackoff returns the backoff used when an eviction is rejected
+// because it would violate a PodDisruptionBudget (HTTP 429). Cap is derived
+// from timeout rather than hard-coded, so the exponential growth is bounded
+// by it instead of by an arbitrary step count; the caller's own deadline
+// check is what actually stops the retries once timeout elapses.
+func evictionRetryBackoff(timeout time.Duration) wait.Backoff {
+	return wait.Backoff{
+		Duration: 1 * time.Second,
+		Factor:   2,
+		Cap:      timeout,
+		Steps:    math.MaxInt32,
+	}
+}
+
 // RemoveDuplicatePods removes the duplicate pods on node. This strategy evicts all duplicate pods on node.
 // A pod is said to be a duplicate of other if both of them are from same creator, kind and are within the same
 // namespace. As of now, this strategy won't evict daemonsets, mirror pods, critical pods and pods with local storages.
-func RemoveDuplicatePods(ds *options.DeschedulerServer, strategy api.DeschedulerStrategy, policyGroupVersion string, nodes []*v1.Node, nodepodCount nodePodEvictedCount) {
+// When strategy.Params.TopologyKeys is set, "duplicate" is scoped to pods that collide within the same topology
+// domain (e.g. zone) rather than the same node, so a creator intentionally spread across domains is left alone.
+func RemoveDuplicatePods(ctx context.Context, ds *options.DeschedulerServer, strategy api.DeschedulerStrategy, policyGroupVersion string, nodes []*v1.Node, nodepodCount nodePodEvictedCount) (StrategyResult, error) {
 	if !strategy.Enabled {
-		return
+		return StrategyResult{}, nil
 	}
-	deleteDuplicatePods(ds.Client, policyGroupVersion, nodes, ds.DryRun, nodepodCount, ds.MaxNoOfPodsToEvictPerNode)
+	timer := prometheus.NewTimer(metrics.StrategyDurationSeconds.WithLabelValues(strategyName))
+	defer timer.ObserveDuration()
+	return deleteDuplicatePods(ctx, ds, policyGroupVersion, nodes, ds.DryRun, nodepodCount, ds.MaxNoOfPodsToEvictPerNode, strategy.Params.TopologyKeys), nil
 }
 
-// deleteDuplicatePods evicts the pod from node and returns the count of evicted pods.
-func deleteDuplicatePods(client clientset.Interface, policyGroupVersion string, nodes []*v1.Node, dryRun bool, nodepodCount nodePodEvictedCount, maxPodsToEvict int) int {
+// deleteDuplicatePods evicts the pod from node and returns a summary of the run.
+// topologyKeys, when non-empty, restricts "duplicate" to pods of the same
+// creator that collide within the same topology domain (see topologyDomain);
+// a creator with one replica per domain is left alone even if several of its
+// pods land on the same node. The walk stops early if ctx is cancelled, e.g.
+// because leadership was lost or the process is shutting down.
+func deleteDuplicatePods(ctx context.Context, ds *options.DeschedulerServer, policyGroupVersion string, nodes []*v1.Node, dryRun bool, nodepodCount nodePodEvictedCount, maxPodsToEvict int, topologyKeys []string) StrategyResult {
 	podsEvicted := 0
-	dpmByNode, creatorIsSaturated := computeCreatorSaturation(client, nodes)
+	podsSkipped := map[string]int{}
+	nodeByName := make(map[string]*v1.Node, len(nodes))
 	for _, node := range nodes {
-		glog.V(1).Infof("Processing node: %#v", node.Name)
-		dpm := dpmByNode[node]
+		nodeByName[node.Name] = node
+	}
+
+	dpmByDomain, creatorIsSaturated, nodesByDomain := computeCreatorSaturation(ds.Client, nodes, topologyKeys)
+domainLoop:
+	for domain, dpm := range dpmByDomain {
+		glog.V(1).Infof("Processing topology domain: %#v (%d node(s))", domain, len(nodesByDomain[domain]))
+		podsSkippedForPDB := 0
 		for creator, pods := range dpm {
-			if len(pods) > 1 && !creatorIsSaturated[creator] {
-				glog.V(1).Infof("%#v", creator)
-				// i = 0 does not evict the first pod
-				for i := 1; i < len(pods); i++ {
-					if maxPodsToEvict > 0 && nodepodCount[node]+1 > maxPodsToEvict {
-						break
-					}
-					success, err := evictions.EvictPod(client, pods[i], policyGroupVersion, dryRun)
-					if !success {
-						glog.Infof("Error when evicting pod: %#v (%#v)", pods[i].Name, err)
-					} else {
-						nodepodCount[node]++
-						glog.V(1).Infof("Evicted pod: %#v (%#v)", pods[i].Name, err)
-					}
+			if len(pods) <= 1 {
+				continue
+			}
+			if creatorIsSaturated[creator] {
+				skipped := len(pods) - 1
+				podsSkipped["saturated"] += skipped
+				metrics.PodsSkippedTotal.WithLabelValues("saturated").Add(float64(skipped))
+				continue
+			}
+			glog.V(1).Infof("%#v", creator)
+			// i = 0 does not evict the first pod
+			for i := 1; i < len(pods); i++ {
+				if ctx.Err() != nil {
+					glog.V(1).Infof("RemoveDuplicatePods cancelled: %v", ctx.Err())
+					break domainLoop
+				}
+				node := nodeByName[pods[i].Spec.NodeName]
+				if maxPodsToEvict > 0 && nodepodCount[node]+1 > maxPodsToEvict {
+					podsSkipped["max-pods-per-node"]++
+					metrics.PodsSkippedTotal.WithLabelValues("max-pods-per-node").Inc()
+					continue
+				}
+				if ds.PlanRecorder != nil {
+					ds.PlanRecorder.Record(plan.Eviction{
+						Pod:              pods[i].Name,
+						Namespace:        pods[i].Namespace,
+						Node:             pods[i].Spec.NodeName,
+						Strategy:         strategyName,
+						Reason:           fmt.Sprintf("duplicate of %s in topology domain %s", creator, domain),
+						OwnerReferences:  ownerRefStrings(pods[i]),
+						CreatorSaturated: creatorIsSaturated[creator],
+					})
+				}
+				success, err := evictPodRespectingPDB(ds, pods[i], policyGroupVersion, dryRun)
+				if err == errPDBViolated {
+					glog.V(1).Infof("Skipping eviction of pod %#v: would violate PodDisruptionBudget", pods[i].Name)
+					podsSkipped["pdb"]++
+					podsSkippedForPDB++
+					metrics.PodsSkippedTotal.WithLabelValues("pdb").Inc()
+					continue
+				}
+				if !success {
+					glog.Infof("Error when evicting pod: %#v (%#v)", pods[i].Name, err)
+				} else {
+					nodepodCount[node]++
+					podsEvicted++
+					metrics.PodsEvictedTotal.WithLabelValues(strategyName, pods[i].Spec.NodeName, pods[i].Namespace).Inc()
+					glog.V(1).Infof("Evicted pod: %#v (%#v)", pods[i].Name, err)
 				}
 			}
 		}
-		podsEvicted += nodepodCount[node]
+		if podsSkippedForPDB > 0 {
+			glog.V(1).Infof("Topology domain %#v: skipped %d duplicate pod(s) due to PodDisruptionBudget", domain, podsSkippedForPDB)
+		}
 	}
-	return podsEvicted
+	return StrategyResult{PodsEvicted: podsEvicted, PodsSkipped: podsSkipped}
+}
+
+// errPDBViolated is returned by evictPodRespectingPDB when the eviction was
+// still rejected by the API server as a PDB violation after all retries.
+var errPDBViolated = apierrors.NewTooManyRequests("eviction would violate PodDisruptionBudget", 0)
+
+// gracePeriodSecondsFor converts the --eviction-grace-period duration into
+// the *int64 seconds evictions.EvictPod expects, returning nil (i.e. defer
+// to the pod's own terminationGracePeriodSeconds) when it is unset.
+func gracePeriodSecondsFor(gracePeriod time.Duration) *int64 {
+	if gracePeriod <= 0 {
+		return nil
+	}
+	seconds := int64(gracePeriod.Seconds())
+	return &seconds
+}
+
+// evictPodRespectingPDB evicts pod via evictions.EvictPod, honoring the pod's
+// matching PodDisruptionBudget and the grace period/timeout configured on ds.
+// If the API server rejects the eviction with 429 (DisruptionBudgetViolated)
+// it is retried with backoff until ds.EvictionTimeout elapses, mirroring the
+// behavior of `kubectl drain`.
+func evictPodRespectingPDB(ds *options.DeschedulerServer, pod *v1.Pod, policyGroupVersion string, dryRun bool) (bool, error) {
+	gracePeriodSeconds := gracePeriodSecondsFor(ds.EvictionGracePeriod)
+	if !ds.PDBAware || dryRun {
+		return evictions.EvictPod(ds.Client, pod, policyGroupVersion, dryRun, gracePeriodSeconds)
+	}
+
+	timeout := effectiveEvictionTimeout(ds.EvictionTimeout)
+	var success bool
+	var lastErr error
+	deadline := time.Now().Add(timeout)
+	backoff := evictionRetryBackoff(timeout)
+	err := wait.ExponentialBackoff(backoff, func() (bool, error) {
+		if time.Now().After(deadline) {
+			return false, errPDBViolated
+		}
+		success, lastErr = evictions.EvictPod(ds.Client, pod, policyGroupVersion, dryRun, gracePeriodSeconds)
+		if success {
+			return true, nil
+		}
+		if apierrors.IsTooManyRequests(lastErr) {
+			// DisruptionBudgetViolated: the PDB's minAvailable would be
+			// violated by this eviction, give the cluster a chance to
+			// recover (e.g. a replacement pod becoming ready) and retry.
+			return false, nil
+		}
+		return false, lastErr
+	})
+	if err == errPDBViolated || (err != nil && apierrors.IsTooManyRequests(err)) {
+		return false, errPDBViolated
+	}
+	if err == wait.ErrWaitTimeout {
+		return false, errPDBViolated
+	}
+	return success, lastErr
 }
 
 // computeCreatorSaturation finds if creators in the cluster are _saturated_.
-// A creator is _saturated_ if atleast one pod is running on every possible nodes.
-// In such a case, pods of this creator are not evicted from any nodes even if duplicates are present.
-func computeCreatorSaturation(client clientset.Interface, nodes []*v1.Node) (map[*v1.Node]DuplicatePodsMap, map[string]bool) {
-	dpmByNode := make(map[*v1.Node]DuplicatePodsMap)
-	creatorAssignedNodes := make(map[string][]*v1.Node)
-	for _, node := range nodes {
-		dpmByNode[node] = ListDuplicatePodsOnANode(client, node)
-		for creator := range dpmByNode[node] {
-			creatorAssignedNodes[creator] = append(creatorAssignedNodes[creator], node)
+// A creator is _saturated_ if it already occupies every topology domain it
+// could possibly be scheduled into (with topologyKeys empty, a domain is a
+// single node, matching the original per-node behavior). In such a case,
+// pods of this creator are not evicted even if duplicates are present.
+func computeCreatorSaturation(client clientset.Interface, nodes []*v1.Node, topologyKeys []string) (map[string]DuplicatePodsMap, map[string]bool, map[string][]*v1.Node) {
+	nodesByDomain := groupNodesByDomain(nodes, topologyKeys)
+
+	dpmByDomain := make(map[string]DuplicatePodsMap, len(nodesByDomain))
+	creatorAssignedDomains := make(map[string][]string)
+	creatorExamplePod := make(map[string]*v1.Pod)
+	for domain, domainNodes := range nodesByDomain {
+		dpmByDomain[domain] = ListDuplicatePodsInDomain(client, domainNodes)
+		for creator, pods := range dpmByDomain[domain] {
+			creatorAssignedDomains[creator] = append(creatorAssignedDomains[creator], domain)
+			creatorExamplePod[creator] = pods[0]
 		}
 	}
 
-	creatorPossibleNodes := make(map[string][]*v1.Node)
-	for creator, nodeList := range creatorAssignedNodes {
-		creatorNode := nodeList[0]
-		creatorPod := dpmByNode[creatorNode][creator][0]
-		for _, node := range nodes {
-			if nodeutil.PodFitsCurrentNode(creatorPod, node) && nodeutil.PodToleratesNodeTaints(creatorPod, node) {
-				creatorPossibleNodes[creator] = append(creatorPossibleNodes[creator], node)
+	creatorPossibleDomains := make(map[string]map[string]bool)
+	for creator, creatorPod := range creatorExamplePod {
+		for domain, domainNodes := range nodesByDomain {
+			for _, node := range domainNodes {
+				if nodeutil.PodFitsCurrentNode(creatorPod, node) && nodeutil.PodToleratesNodeTaints(creatorPod, node) {
+					if creatorPossibleDomains[creator] == nil {
+						creatorPossibleDomains[creator] = make(map[string]bool)
+					}
+					creatorPossibleDomains[creator][domain] = true
+					break
+				}
 			}
 		}
 	}
 
 	creatorIsSaturated := make(map[string]bool)
-	for creator, nodeList := range creatorAssignedNodes {
-		creatorIsSaturated[creator] = (len(creatorPossibleNodes[creator]) == len(nodeList))
+	for creator, domains := range creatorAssignedDomains {
+		creatorIsSaturated[creator] = (len(creatorPossibleDomains[creator]) == len(domains))
 		glog.V(1).Infof("Creator %#v is saturated: %#v", creator, creatorIsSaturated[creator])
 	}
 
-	return dpmByNode, creatorIsSaturated
+	return dpmByDomain, creatorIsSaturated, nodesByDomain
 }
 
-// ListDuplicatePodsOnANode lists duplicate pods on a given node.
-func ListDuplicatePodsOnANode(client clientset.Interface, node *v1.Node) DuplicatePodsMap {
-	pods, err := podutil.ListEvictablePodsOnNode(client, node)
-	if err != nil {
-		return nil
+// ListDuplicatePodsInDomain lists duplicate pods across every node in a
+// single topology domain.
+func ListDuplicatePodsInDomain(client clientset.Interface, domainNodes []*v1.Node) DuplicatePodsMap {
+	var pods []*v1.Pod
+	for _, node := range domainNodes {
+		nodePods, err := podutil.ListEvictablePodsOnNode(client, node)
+		if err != nil {
+			continue
+		}
+		pods = append(pods, nodePods...)
 	}
 	return FindDuplicatePods(pods)
 }
@@ -131,3 +299,13 @@ func FindDuplicatePods(pods []*v1.Pod) DuplicatePodsMap {
 	}
 	return dpm
 }
+
+// ownerRefStrings renders pod's owner references as "Kind/Name" strings for
+// inclusion in a plan.Eviction.
+func ownerRefStrings(pod *v1.Pod) []string {
+	refs := make([]string, 0, len(pod.OwnerReferences))
+	for _, ref := range pod.OwnerReferences {
+		refs = append(refs, strings.Join([]string{ref.Kind, ref.Name}, "/"))
+	}
+	return refs
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+)
+
+// fakeClientWithPods returns a fake clientset whose pod List honors the
+// spec.nodeName field selector ListPodsOnNode relies on; the stock fake
+// object tracker only filters by label.
+func fakeClientWithPods(pods ...*v1.Pod) *fake.Clientset {
+	client := &fake.Clientset{}
+	client.AddReactor("list", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		selector := action.(core.ListAction).GetListRestrictions().Fields
+		list := &v1.PodList{}
+		for _, p := range pods {
+			if selector.Matches(fields.Set{"spec.nodeName": p.Spec.NodeName}) {
+				list.Items = append(list.Items, *p)
+			}
+		}
+		return true, list, nil
+	})
+	return client
+}
+
+func duplicatesTestPod(name, namespace, nodeName, ownerKind, ownerName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: ownerKind, Name: ownerName},
+			},
+		},
+		Spec: v1.PodSpec{NodeName: nodeName},
+	}
+}
+
+// TestComputeCreatorSaturationMultiZone covers the scenario the
+// topologyKeys support is meant for: a creator with one pod per zone is
+// already spread the way we want and should be left alone, while a creator
+// with several pods crammed into a single zone is a real duplicate that
+// still has room to spread and should not be considered saturated.
+func TestComputeCreatorSaturationMultiZone(t *testing.T) {
+	zoneKey := []string{"topology.kubernetes.io/zone"}
+	nodeA1 := nodeWithLabels("node-a1", map[string]string{"topology.kubernetes.io/zone": "zone-a"})
+	nodeA2 := nodeWithLabels("node-a2", map[string]string{"topology.kubernetes.io/zone": "zone-a"})
+	nodeB1 := nodeWithLabels("node-b1", map[string]string{"topology.kubernetes.io/zone": "zone-b"})
+	nodes := []*v1.Node{nodeA1, nodeA2, nodeB1}
+
+	// "web" is spread across both zones already: one pod per domain.
+	webA := duplicatesTestPod("web-1", "default", "node-a1", "ReplicaSet", "web")
+	webB := duplicatesTestPod("web-2", "default", "node-b1", "ReplicaSet", "web")
+	// "cache" has both its pods piled onto zone-a even though zone-b has room.
+	cacheA1 := duplicatesTestPod("cache-1", "default", "node-a1", "ReplicaSet", "cache")
+	cacheA2 := duplicatesTestPod("cache-2", "default", "node-a2", "ReplicaSet", "cache")
+
+	client := fakeClientWithPods(webA, webB, cacheA1, cacheA2)
+
+	dpmByDomain, creatorIsSaturated, nodesByDomain := computeCreatorSaturation(client, nodes, zoneKey)
+
+	if len(nodesByDomain["zone-a"]) != 2 || len(nodesByDomain["zone-b"]) != 1 {
+		t.Fatalf("unexpected domain grouping: %#v", nodesByDomain)
+	}
+
+	if got := len(dpmByDomain["zone-a"]["ReplicaSet/cache"]); got != 2 {
+		t.Errorf("expected 2 pods for ReplicaSet/cache in zone-a, got %d", got)
+	}
+	if got := len(dpmByDomain["zone-a"]["ReplicaSet/web"]); got != 1 {
+		t.Errorf("expected 1 pod for ReplicaSet/web in zone-a, got %d", got)
+	}
+	if got := len(dpmByDomain["zone-b"]["ReplicaSet/web"]); got != 1 {
+		t.Errorf("expected 1 pod for ReplicaSet/web in zone-b, got %d", got)
+	}
+
+	if !creatorIsSaturated["ReplicaSet/web"] {
+		t.Errorf("expected ReplicaSet/web (one pod per zone) to be saturated, since it already occupies every zone it could be spread across")
+	}
+	if creatorIsSaturated["ReplicaSet/cache"] {
+		t.Errorf("expected ReplicaSet/cache (both pods in zone-a) to not be saturated, since zone-b is still available to spread into")
+	}
+}
+
+// TestComputeCreatorSaturationSingleNodeDomain confirms that with no
+// topologyKeys configured, behavior matches the original per-node duplicate
+// detection: each node is its own domain.
+func TestComputeCreatorSaturationSingleNodeDomain(t *testing.T) {
+	node1 := nodeWithLabels("node-1", nil)
+	node2 := nodeWithLabels("node-2", nil)
+	nodes := []*v1.Node{node1, node2}
+
+	pod1 := duplicatesTestPod("app-1", "default", "node-1", "ReplicaSet", "app")
+	pod2 := duplicatesTestPod("app-2", "default", "node-1", "ReplicaSet", "app")
+
+	client := fakeClientWithPods(pod1, pod2)
+
+	dpmByDomain, creatorIsSaturated, nodesByDomain := computeCreatorSaturation(client, nodes, nil)
+
+	if len(nodesByDomain) != 2 {
+		t.Fatalf("expected each node to be its own domain, got %d domains", len(nodesByDomain))
+	}
+	if got := len(dpmByDomain["node-1"]["ReplicaSet/app"]); got != 2 {
+		t.Errorf("expected 2 pods for ReplicaSet/app on node-1, got %d", got)
+	}
+	if creatorIsSaturated["ReplicaSet/app"] {
+		t.Errorf("expected ReplicaSet/app to not be saturated, since node-2 is still available")
+	}
+}
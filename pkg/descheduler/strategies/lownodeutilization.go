@@ -0,0 +1,356 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	"github.com/kubernetes-incubator/descheduler/cmd/descheduler/app/options"
+	"github.com/kubernetes-incubator/descheduler/pkg/api"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/evictions"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/metrics"
+	"github.com/kubernetes-incubator/descheduler/pkg/descheduler/plan"
+	podutil "github.com/kubernetes-incubator/descheduler/pkg/descheduler/pod"
+)
+
+// lowNodeUtilizationStrategyName is used to label metrics emitted by this strategy.
+const lowNodeUtilizationStrategyName = "LowNodeUtilization"
+
+// usageResources are the resource types LowNodeUtilization considers when
+// classifying a node as under/appropriately/over utilized.
+var usageResources = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}
+
+// nodeUsage is a node together with its resource usage, expressed as a
+// percentage of allocatable, and the evictable pods running on it ordered
+// from most to least preferred eviction candidate.
+type nodeUsage struct {
+	node          *v1.Node
+	usagePercent  map[v1.ResourceName]float64
+	evictablePods []*v1.Pod
+}
+
+func init() {
+	DefaultRegistry.Register(Entry{Name: lowNodeUtilizationStrategyName, Run: LowNodeUtilization})
+}
+
+// LowNodeUtilization moves pods off of over-utilized nodes and onto
+// under-utilized ones by evicting them, relying on the scheduler to place
+// their replacements better. A node is "underutilized" if every resource in
+// strategy.Params.NodeResourceUtilizationThresholds.Thresholds is below its
+// threshold, "overutilized" if any resource is above its target threshold in
+// TargetThresholds, and "appropriately utilized" otherwise. Usage is read
+// from the metrics API when ds.MetricsClient is configured, falling back to
+// summed pod resource requests otherwise.
+func LowNodeUtilization(ctx context.Context, ds *options.DeschedulerServer, strategy api.DeschedulerStrategy, policyGroupVersion string, nodes []*v1.Node, nodepodCount nodePodEvictedCount) (StrategyResult, error) {
+	if !strategy.Enabled {
+		return StrategyResult{}, nil
+	}
+	timer := prometheus.NewTimer(metrics.StrategyDurationSeconds.WithLabelValues(lowNodeUtilizationStrategyName))
+	defer timer.ObserveDuration()
+
+	thresholds := strategy.Params.NodeResourceUtilizationThresholds.Thresholds
+	targetThresholds := strategy.Params.NodeResourceUtilizationThresholds.TargetThresholds
+
+	usages := make([]nodeUsage, 0, len(nodes))
+	for _, node := range nodes {
+		usage, err := computeNodeUsage(ds, node)
+		if err != nil {
+			glog.Errorf("Unable to compute usage for node %#v: %v", node.Name, err)
+			continue
+		}
+		usages = append(usages, usage)
+	}
+
+	var underutilized, overutilized []nodeUsage
+	for _, u := range usages {
+		switch {
+		case belowAll(u.usagePercent, thresholds):
+			underutilized = append(underutilized, u)
+		case aboveAny(u.usagePercent, targetThresholds):
+			overutilized = append(overutilized, u)
+		}
+	}
+	if len(underutilized) == 0 {
+		glog.V(1).Infof("No underutilized nodes found, nothing to do")
+		return StrategyResult{}, nil
+	}
+	if len(overutilized) == 0 {
+		glog.V(1).Infof("No overutilized nodes found, nothing to do")
+		return StrategyResult{}, nil
+	}
+
+	headroom := availableHeadroom(underutilized, targetThresholds)
+
+	podsEvicted := 0
+	podsSkipped := map[string]int{}
+	for _, u := range overutilized {
+		sortPodsForEviction(u.evictablePods)
+		for i, pod := range u.evictablePods {
+			if ctx.Err() != nil {
+				glog.V(1).Infof("LowNodeUtilization cancelled: %v", ctx.Err())
+				return StrategyResult{PodsEvicted: podsEvicted, PodsSkipped: podsSkipped}, ctx.Err()
+			}
+			pressured := pressuredResources(u.usagePercent, targetThresholds)
+			if len(pressured) == 0 || headroomExhaustedForResources(headroom, pressured) {
+				break
+			}
+			if ds.MaxNoOfPodsToEvictPerNode > 0 && nodepodCount[u.node]+1 > ds.MaxNoOfPodsToEvictPerNode {
+				remaining := len(u.evictablePods) - i
+				podsSkipped["max-pods-per-node"] += remaining
+				metrics.PodsSkippedTotal.WithLabelValues("max-pods-per-node").Add(float64(remaining))
+				break
+			}
+			if ds.PlanRecorder != nil {
+				ds.PlanRecorder.Record(plan.Eviction{
+					Pod:       pod.Name,
+					Namespace: pod.Namespace,
+					Node:      u.node.Name,
+					Strategy:  lowNodeUtilizationStrategyName,
+					Reason:    "node overutilized",
+				})
+			}
+			success, err := evictions.EvictPod(ds.Client, pod, policyGroupVersion, ds.DryRun, gracePeriodSecondsFor(ds.EvictionGracePeriod))
+			if !success {
+				glog.Infof("Error when evicting pod: %#v (%#v)", pod.Name, err)
+				continue
+			}
+			nodepodCount[u.node]++
+			podsEvicted++
+			metrics.PodsEvictedTotal.WithLabelValues(lowNodeUtilizationStrategyName, u.node.Name, pod.Namespace).Inc()
+			subtractPodUsage(u.usagePercent, pod, u.node)
+			consumeHeadroom(headroom, pod, u.node)
+		}
+	}
+	return StrategyResult{PodsEvicted: podsEvicted, PodsSkipped: podsSkipped}, nil
+}
+
+// computeNodeUsage returns node's current usage (as a percentage of
+// allocatable, per resource) and its evictable pods. CPU and memory usage
+// are read from the metrics API when ds.MetricsClient is configured,
+// falling back to summed pod resource requests if it isn't, or if the
+// metrics API call fails (e.g. metrics-server isn't deployed yet).
+func computeNodeUsage(ds *options.DeschedulerServer, node *v1.Node) (nodeUsage, error) {
+	pods, err := podutil.ListEvictablePodsOnNode(ds.Client, node)
+	if err != nil {
+		return nodeUsage{}, err
+	}
+	allPods, err := podutil.ListPodsOnNode(ds.Client, node)
+	if err != nil {
+		return nodeUsage{}, err
+	}
+
+	totals := map[v1.ResourceName]*resource.Quantity{
+		v1.ResourceCPU:    resource.NewQuantity(0, resource.DecimalSI),
+		v1.ResourceMemory: resource.NewQuantity(0, resource.BinarySI),
+	}
+	if cpu, mem, err := nodeUsageFromMetrics(ds.MetricsClient, node); err == nil {
+		totals[v1.ResourceCPU] = cpu
+		totals[v1.ResourceMemory] = mem
+	} else {
+		glog.V(4).Infof("Falling back to summed pod requests for node %#v usage: %v", node.Name, err)
+		for _, pod := range allPods {
+			for _, c := range pod.Spec.Containers {
+				if cpu := c.Resources.Requests.Cpu(); cpu != nil {
+					totals[v1.ResourceCPU].Add(*cpu)
+				}
+				if mem := c.Resources.Requests.Memory(); mem != nil {
+					totals[v1.ResourceMemory].Add(*mem)
+				}
+			}
+		}
+	}
+
+	usagePercent := map[v1.ResourceName]float64{}
+	for _, res := range usageResources {
+		switch res {
+		case v1.ResourcePods:
+			if allocatable, ok := node.Status.Allocatable[v1.ResourcePods]; ok && allocatable.Value() > 0 {
+				usagePercent[res] = 100 * float64(len(allPods)) / float64(allocatable.Value())
+			}
+		default:
+			if allocatable, ok := node.Status.Allocatable[res]; ok && allocatable.MilliValue() > 0 {
+				usagePercent[res] = 100 * float64(totals[res].MilliValue()) / float64(allocatable.MilliValue())
+			}
+		}
+	}
+
+	return nodeUsage{node: node, usagePercent: usagePercent, evictablePods: pods}, nil
+}
+
+// nodeUsageFromMetrics fetches node's actual observed CPU and memory usage
+// via the metrics.k8s.io NodeMetrics API. It returns an error if
+// metricsClient is nil (not configured) or the lookup fails, so callers can
+// fall back to requests-based estimation.
+func nodeUsageFromMetrics(metricsClient metricsclientset.Interface, node *v1.Node) (*resource.Quantity, *resource.Quantity, error) {
+	if metricsClient == nil {
+		return nil, nil, fmt.Errorf("no metrics client configured")
+	}
+	nodeMetrics, err := metricsClient.MetricsV1beta1().NodeMetricses().Get(node.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	cpu := nodeMetrics.Usage.Cpu().DeepCopy()
+	mem := nodeMetrics.Usage.Memory().DeepCopy()
+	return &cpu, &mem, nil
+}
+
+func belowAll(usage map[v1.ResourceName]float64, thresholds api.ResourceThresholds) bool {
+	for res, threshold := range thresholds {
+		if usage[res] >= float64(threshold) {
+			return false
+		}
+	}
+	return true
+}
+
+func aboveAny(usage map[v1.ResourceName]float64, thresholds api.ResourceThresholds) bool {
+	for res, threshold := range thresholds {
+		if usage[res] > float64(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// availableHeadroom sums, per resource, how far each underutilized node is
+// below its target threshold, i.e. how much usage the cluster can absorb by moving
+// pods onto it.
+func availableHeadroom(underutilized []nodeUsage, targetThresholds api.ResourceThresholds) map[v1.ResourceName]float64 {
+	headroom := map[v1.ResourceName]float64{}
+	for res, threshold := range targetThresholds {
+		for _, u := range underutilized {
+			headroom[res] += float64(threshold) - u.usagePercent[res]
+		}
+	}
+	return headroom
+}
+
+// pressuredResources returns the resources in usage that are over their
+// target threshold, i.e. the resources a node actually needs relief on.
+func pressuredResources(usage map[v1.ResourceName]float64, targetThresholds api.ResourceThresholds) []v1.ResourceName {
+	var pressured []v1.ResourceName
+	for res, threshold := range targetThresholds {
+		if usage[res] > float64(threshold) {
+			pressured = append(pressured, res)
+		}
+	}
+	return pressured
+}
+
+// headroomExhaustedForResources reports whether every resource in
+// pressuredResources has no headroom left. A node stops being evicted from
+// once none of the resources it's actually over-target on can be absorbed
+// elsewhere; headroom on resources it isn't pressured on is irrelevant to it,
+// e.g. cluster-wide pod-count headroom running out shouldn't halt eviction of
+// a node that's only over its CPU target.
+func headroomExhaustedForResources(headroom map[v1.ResourceName]float64, pressuredResources []v1.ResourceName) bool {
+	for _, res := range pressuredResources {
+		if headroom[res] > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// consumeHeadroom decrements the remaining headroom by pod's actual
+// requested-resource percentage of node's allocatable, the same
+// percentage-point units availableHeadroom was computed in, so headroom is
+// exhausted once the cluster has genuinely absorbed that much usage rather
+// than after a fixed number of evictions.
+func consumeHeadroom(headroom map[v1.ResourceName]float64, pod *v1.Pod, node *v1.Node) {
+	for res, percent := range podResourceUsagePercent(pod, node) {
+		if _, ok := headroom[res]; ok {
+			headroom[res] -= percent
+		}
+	}
+}
+
+// subtractPodUsage updates a node's running usagePercent estimate after pod
+// is evicted from it, so later iterations see the node's usage drop.
+func subtractPodUsage(usagePercent map[v1.ResourceName]float64, pod *v1.Pod, node *v1.Node) {
+	for res, percent := range podResourceUsagePercent(pod, node) {
+		usagePercent[res] -= percent
+	}
+}
+
+// podResourceUsagePercent returns, per resource, how much of node's
+// allocatable pod's own requests (or, for v1.ResourcePods, pod's single
+// slot) account for.
+func podResourceUsagePercent(pod *v1.Pod, node *v1.Node) map[v1.ResourceName]float64 {
+	percent := map[v1.ResourceName]float64{}
+	for _, c := range pod.Spec.Containers {
+		if cpu := c.Resources.Requests.Cpu(); cpu != nil {
+			if allocatable, ok := node.Status.Allocatable[v1.ResourceCPU]; ok && allocatable.MilliValue() > 0 {
+				percent[v1.ResourceCPU] += 100 * float64(cpu.MilliValue()) / float64(allocatable.MilliValue())
+			}
+		}
+		if mem := c.Resources.Requests.Memory(); mem != nil {
+			if allocatable, ok := node.Status.Allocatable[v1.ResourceMemory]; ok && allocatable.MilliValue() > 0 {
+				percent[v1.ResourceMemory] += 100 * float64(mem.MilliValue()) / float64(allocatable.MilliValue())
+			}
+		}
+	}
+	if allocatable, ok := node.Status.Allocatable[v1.ResourcePods]; ok && allocatable.Value() > 0 {
+		percent[v1.ResourcePods] = 100 / float64(allocatable.Value())
+	}
+	return percent
+}
+
+// podQoSRank orders pods for eviction: BestEffort first, then Burstable,
+// then Guaranteed, mirroring the kubelet's own eviction preference.
+func podQoSRank(pod *v1.Pod) int {
+	switch pod.Status.QOSClass {
+	case v1.PodQOSBestEffort:
+		return 0
+	case v1.PodQOSBurstable:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// sortPodsForEviction orders pods by QoS class (BestEffort before Burstable
+// before Guaranteed), then by total requested CPU descending so the biggest
+// CPU consumers within a class are evicted first.
+func sortPodsForEviction(pods []*v1.Pod) {
+	sort.Slice(pods, func(i, j int) bool {
+		if ri, rj := podQoSRank(pods[i]), podQoSRank(pods[j]); ri != rj {
+			return ri < rj
+		}
+		return podRequestedCPU(pods[i]) > podRequestedCPU(pods[j])
+	})
+}
+
+func podRequestedCPU(pod *v1.Pod) int64 {
+	var total int64
+	for _, c := range pod.Spec.Containers {
+		if cpu := c.Resources.Requests.Cpu(); cpu != nil {
+			total += cpu.MilliValue()
+		}
+	}
+	return total
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/descheduler/pkg/api"
+)
+
+func nodeWithAllocatable(name string, cpu, memory, pods int64) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+				v1.ResourcePods:   *resource.NewQuantity(pods, resource.DecimalSI),
+			},
+		},
+	}
+}
+
+func podWithRequests(name string, cpu, memory int64) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+							v1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBelowAll(t *testing.T) {
+	thresholds := api.ResourceThresholds{v1.ResourceCPU: 30, v1.ResourceMemory: 30}
+	tests := []struct {
+		name  string
+		usage map[v1.ResourceName]float64
+		want  bool
+	}{
+		{"all below threshold", map[v1.ResourceName]float64{v1.ResourceCPU: 10, v1.ResourceMemory: 20}, true},
+		{"one at threshold", map[v1.ResourceName]float64{v1.ResourceCPU: 30, v1.ResourceMemory: 20}, false},
+		{"one above threshold", map[v1.ResourceName]float64{v1.ResourceCPU: 40, v1.ResourceMemory: 20}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := belowAll(tt.usage, thresholds); got != tt.want {
+				t.Errorf("belowAll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAboveAny(t *testing.T) {
+	thresholds := api.ResourceThresholds{v1.ResourceCPU: 70, v1.ResourceMemory: 70}
+	tests := []struct {
+		name  string
+		usage map[v1.ResourceName]float64
+		want  bool
+	}{
+		{"all below threshold", map[v1.ResourceName]float64{v1.ResourceCPU: 50, v1.ResourceMemory: 50}, false},
+		{"one at threshold", map[v1.ResourceName]float64{v1.ResourceCPU: 70, v1.ResourceMemory: 50}, false},
+		{"one above threshold", map[v1.ResourceName]float64{v1.ResourceCPU: 80, v1.ResourceMemory: 50}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := aboveAny(tt.usage, thresholds); got != tt.want {
+				t.Errorf("aboveAny() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAvailableHeadroom(t *testing.T) {
+	targetThresholds := api.ResourceThresholds{v1.ResourceCPU: 70}
+	underutilized := []nodeUsage{
+		{usagePercent: map[v1.ResourceName]float64{v1.ResourceCPU: 30}},
+		{usagePercent: map[v1.ResourceName]float64{v1.ResourceCPU: 50}},
+	}
+
+	headroom := availableHeadroom(underutilized, targetThresholds)
+
+	// (70-30) + (70-50) = 40 + 20
+	if got, want := headroom[v1.ResourceCPU], 60.0; got != want {
+		t.Errorf("headroom[cpu] = %v, want %v", got, want)
+	}
+}
+
+func TestPressuredResources(t *testing.T) {
+	targetThresholds := api.ResourceThresholds{v1.ResourceCPU: 70, v1.ResourcePods: 70}
+	usage := map[v1.ResourceName]float64{v1.ResourceCPU: 90, v1.ResourcePods: 50}
+
+	got := pressuredResources(usage, targetThresholds)
+
+	if len(got) != 1 || got[0] != v1.ResourceCPU {
+		t.Errorf("pressuredResources() = %#v, want [cpu]", got)
+	}
+}
+
+func TestHeadroomExhaustedForResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		headroom  map[v1.ResourceName]float64
+		pressured []v1.ResourceName
+		want      bool
+	}{
+		{
+			name:      "pressured resource still has headroom",
+			headroom:  map[v1.ResourceName]float64{v1.ResourceCPU: 10, v1.ResourcePods: 0},
+			pressured: []v1.ResourceName{v1.ResourceCPU},
+			want:      false,
+		},
+		{
+			name:      "pressured resource exhausted even though another resource has headroom",
+			headroom:  map[v1.ResourceName]float64{v1.ResourceCPU: 0, v1.ResourcePods: 10},
+			pressured: []v1.ResourceName{v1.ResourceCPU},
+			want:      true,
+		},
+		{
+			name:      "one exhausted pressured resource doesn't block while another pressured resource has headroom",
+			headroom:  map[v1.ResourceName]float64{v1.ResourceCPU: 10, v1.ResourcePods: 0},
+			pressured: []v1.ResourceName{v1.ResourceCPU, v1.ResourcePods},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := headroomExhaustedForResources(tt.headroom, tt.pressured); got != tt.want {
+				t.Errorf("headroomExhaustedForResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsumeHeadroom(t *testing.T) {
+	node := nodeWithAllocatable("node-a", 1000, 1000, 10)
+	pod := podWithRequests("pod-a", 100, 100)
+	headroom := map[v1.ResourceName]float64{v1.ResourceCPU: 50, v1.ResourceMemory: 50}
+
+	consumeHeadroom(headroom, pod, node)
+
+	if got, want := headroom[v1.ResourceCPU], 40.0; got != want {
+		t.Errorf("headroom[cpu] = %v, want %v", got, want)
+	}
+	if got, want := headroom[v1.ResourceMemory], 40.0; got != want {
+		t.Errorf("headroom[memory] = %v, want %v", got, want)
+	}
+}
+
+func TestSortPodsForEviction(t *testing.T) {
+	best := podWithRequests("best-effort", 100, 100)
+	best.Status.QOSClass = v1.PodQOSBestEffort
+	burstableSmall := podWithRequests("burstable-small", 100, 100)
+	burstableSmall.Status.QOSClass = v1.PodQOSBurstable
+	burstableBig := podWithRequests("burstable-big", 500, 100)
+	burstableBig.Status.QOSClass = v1.PodQOSBurstable
+	guaranteed := podWithRequests("guaranteed", 100, 100)
+	guaranteed.Status.QOSClass = v1.PodQOSGuaranteed
+
+	pods := []*v1.Pod{guaranteed, burstableSmall, best, burstableBig}
+	sortPodsForEviction(pods)
+
+	want := []string{"best-effort", "burstable-big", "burstable-small", "guaranteed"}
+	for i, name := range want {
+		if pods[i].Name != name {
+			t.Errorf("pods[%d] = %q, want %q", i, pods[i].Name, name)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/descheduler/cmd/descheduler/app/options"
+	"github.com/kubernetes-incubator/descheduler/pkg/api"
+)
+
+// nodePodEvictedCount tracks how many pods have been evicted from each
+// node over the course of a single descheduler run, so strategies sharing
+// one run can jointly enforce ds.MaxNoOfPodsToEvictPerNode.
+type nodePodEvictedCount map[*v1.Node]int
+
+// StrategyResult summarizes the outcome of a single strategy run.
+type StrategyResult struct {
+	// PodsEvicted is the number of pods the strategy successfully evicted.
+	PodsEvicted int
+	// PodsSkipped counts pods the strategy decided not to evict, keyed by reason.
+	PodsSkipped map[string]int
+}
+
+// StrategyFunc is the signature every registered strategy implements. It
+// mirrors the existing hand-written strategy functions (RemoveDuplicatePods)
+// so that registering a strategy requires no change to its implementation.
+// ctx is checked between evictions so a strategy run can be cancelled
+// mid-loop, e.g. when leadership is lost or the process is shutting down.
+type StrategyFunc func(ctx context.Context, ds *options.DeschedulerServer, strategy api.DeschedulerStrategy, policyGroupVersion string, nodes []*v1.Node, nodepodCount nodePodEvictedCount) (StrategyResult, error)
+
+// Entry is what a strategy registers with the Registry.
+type Entry struct {
+	// Name is the key used in the descheduler policy's strategies map.
+	Name string
+	// Run executes the strategy.
+	Run StrategyFunc
+}
+
+// Registry holds the set of known strategies, keyed by name. Out-of-tree
+// strategies can Register themselves from an init() function in their own
+// package as long as that package is imported (e.g. blank-imported) by the
+// descheduler binary.
+type Registry struct {
+	mu         sync.RWMutex
+	strategies map[string]Entry
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{strategies: make(map[string]Entry)}
+}
+
+// DefaultRegistry is the registry used by the descheduler command.
+var DefaultRegistry = NewRegistry()
+
+// Register adds entry under entry.Name, replacing any previous registration
+// of the same name.
+func (r *Registry) Register(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.strategies[entry.Name] = entry
+}
+
+// Get returns the entry registered under name, if any.
+func (r *Registry) Get(name string) (Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.strategies[name]
+	return entry, ok
+}
+
+// Names returns the names of all registered strategies.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.strategies))
+	for name := range r.strategies {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Run looks up name and executes it, returning an error if name is unknown.
+func (r *Registry) Run(ctx context.Context, name string, ds *options.DeschedulerServer, strategy api.DeschedulerStrategy, policyGroupVersion string, nodes []*v1.Node, nodepodCount nodePodEvictedCount) (StrategyResult, error) {
+	entry, ok := r.Get(name)
+	if !ok {
+		return StrategyResult{}, fmt.Errorf("no strategy registered under name %q", name)
+	}
+	return entry.Run(ctx, ds, strategy, policyGroupVersion, nodes, nodepodCount)
+}
+
+func init() {
+	DefaultRegistry.Register(Entry{Name: strategyName, Run: RemoveDuplicatePods})
+}
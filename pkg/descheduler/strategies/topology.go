@@ -0,0 +1,57 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"strings"
+
+	"k8s.io/api/core/v1"
+)
+
+// defaultTopologyKey is used when a strategy is not configured with
+// topologyKeys, preserving the original node-only duplicate detection.
+const defaultTopologyKey = "kubernetes.io/hostname"
+
+// topologyDomain returns the identifier of the topology domain node belongs
+// to for the given topologyKeys, built by joining the node's label value for
+// each key in order. Nodes missing a label for one of the keys fall back to
+// the node name for that component, so they never spuriously collide with
+// another node's domain.
+func topologyDomain(node *v1.Node, topologyKeys []string) string {
+	if len(topologyKeys) == 0 {
+		topologyKeys = []string{defaultTopologyKey}
+	}
+	parts := make([]string, 0, len(topologyKeys))
+	for _, key := range topologyKeys {
+		if v, ok := node.Labels[key]; ok {
+			parts = append(parts, v)
+		} else {
+			parts = append(parts, node.Name)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// groupNodesByDomain buckets nodes by their topologyDomain.
+func groupNodesByDomain(nodes []*v1.Node, topologyKeys []string) map[string][]*v1.Node {
+	domains := make(map[string][]*v1.Node)
+	for _, node := range nodes {
+		domain := topologyDomain(node, topologyKeys)
+		domains[domain] = append(domains[domain], node)
+	}
+	return domains
+}
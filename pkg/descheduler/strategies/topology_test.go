@@ -0,0 +1,91 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strategies
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func nodeWithLabels(name string, labels map[string]string) *v1.Node {
+	return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestTopologyDomain(t *testing.T) {
+	tests := []struct {
+		name         string
+		node         *v1.Node
+		topologyKeys []string
+		want         string
+	}{
+		{
+			name:         "no topologyKeys falls back to hostname label",
+			node:         nodeWithLabels("node-a1", map[string]string{defaultTopologyKey: "node-a1"}),
+			topologyKeys: nil,
+			want:         "node-a1",
+		},
+		{
+			name:         "single zone key",
+			node:         nodeWithLabels("node-a1", map[string]string{"topology.kubernetes.io/zone": "zone-a"}),
+			topologyKeys: []string{"topology.kubernetes.io/zone"},
+			want:         "zone-a",
+		},
+		{
+			name: "multiple keys are joined in order",
+			node: nodeWithLabels("node-a1", map[string]string{
+				"topology.kubernetes.io/region": "region-1",
+				"topology.kubernetes.io/zone":   "zone-a",
+			}),
+			topologyKeys: []string{"topology.kubernetes.io/region", "topology.kubernetes.io/zone"},
+			want:         "region-1/zone-a",
+		},
+		{
+			name:         "missing label falls back to the node name for that component",
+			node:         nodeWithLabels("node-a1", map[string]string{}),
+			topologyKeys: []string{"topology.kubernetes.io/zone"},
+			want:         "node-a1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topologyDomain(tt.node, tt.topologyKeys); got != tt.want {
+				t.Errorf("topologyDomain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupNodesByDomain(t *testing.T) {
+	zoneKey := []string{"topology.kubernetes.io/zone"}
+	nodeA1 := nodeWithLabels("node-a1", map[string]string{"topology.kubernetes.io/zone": "zone-a"})
+	nodeA2 := nodeWithLabels("node-a2", map[string]string{"topology.kubernetes.io/zone": "zone-a"})
+	nodeB1 := nodeWithLabels("node-b1", map[string]string{"topology.kubernetes.io/zone": "zone-b"})
+
+	got := groupNodesByDomain([]*v1.Node{nodeA1, nodeA2, nodeB1}, zoneKey)
+
+	want := map[string][]*v1.Node{
+		"zone-a": {nodeA1, nodeA2},
+		"zone-b": {nodeB1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupNodesByDomain() = %#v, want %#v", got, want)
+	}
+}